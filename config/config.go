@@ -1,13 +1,24 @@
 package config
 
+import "os"
+
 // Config holds the application configuration.
 type Config struct {
 	Port string
+
+	// AuthToken is the bearer token required by the Auth middleware.
+	AuthToken string
 }
 
 // LoadConfig loads configuration for the application.
 func LoadConfig() *Config {
+	authToken := os.Getenv("HORTEN_AUTH_TOKEN")
+	if authToken == "" {
+		authToken = "dev-secret"
+	}
+
 	return &Config{
-		Port: "8080",
+		Port:      "8080",
+		AuthToken: authToken,
 	}
 }
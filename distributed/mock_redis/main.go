@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"sync"
 	"time"
@@ -9,16 +10,23 @@ import (
 
 var ctx = context.Background()
 
-// MockRedis simulates a Redis client with basic lock functionality
+// MockRedis simulates a Redis client with basic lock functionality,
+// including the atomic compare-and-delete/compare-and-expire and INCR
+// operations RedisLock needs for Lua-script-backed release/refresh and
+// fencing tokens.
 type MockRedis struct {
-	data map[string]string
-	mu   sync.Mutex
+	data     map[string]string
+	expireAt map[string]time.Time
+	counters map[string]int64
+	mu       sync.Mutex
 }
 
 // NewMockRedis creates a new MockRedis instance
 func NewMockRedis() *MockRedis {
 	return &MockRedis{
-		data: make(map[string]string),
+		data:     make(map[string]string),
+		expireAt: make(map[string]time.Time),
+		counters: make(map[string]int64),
 	}
 }
 
@@ -33,6 +41,7 @@ func (r *MockRedis) SetNX(key, value string, ttl time.Duration) (bool, error) {
 
 	// Simulate setting the value with a TTL
 	r.data[key] = value
+	r.expireAt[key] = time.Now().Add(ttl)
 	go func() {
 		time.Sleep(ttl)
 		r.mu.Lock()
@@ -40,6 +49,7 @@ func (r *MockRedis) SetNX(key, value string, ttl time.Duration) (bool, error) {
 		// Only delete if the same value still exists (avoid deleting newer locks)
 		if r.data[key] == value {
 			delete(r.data, key)
+			delete(r.expireAt, key)
 		}
 	}()
 
@@ -69,9 +79,52 @@ func (r *MockRedis) Del(key string) (int, error) {
 	}
 
 	delete(r.data, key)
+	delete(r.expireAt, key)
 	return 1, nil
 }
 
+// Eval simulates the subset of EVAL the release/refresh scripts need:
+// compare-and-delete and compare-and-expire, both evaluated atomically
+// under r.mu so a TTL expiry racing a real holder's release/refresh can
+// never observe or act on a stale value. A successful refresh only
+// records the new deadline in expireAt; the expiry goroutine started by
+// SetNX does not read expireAt, so a refreshed key still expires at its
+// original TTL.
+func (r *MockRedis) Eval(script string, keys []string, args []interface{}) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, value := keys[0], args[0].(string)
+
+	switch script {
+	case releaseScript:
+		if r.data[key] == value {
+			delete(r.data, key)
+			delete(r.expireAt, key)
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case refreshScript:
+		if r.data[key] != value {
+			return int64(0), nil
+		}
+		ttlMillis := args[1].(int64)
+		r.expireAt[key] = time.Now().Add(time.Duration(ttlMillis) * time.Millisecond)
+		return int64(1), nil
+	default:
+		return nil, fmt.Errorf("unsupported script")
+	}
+}
+
+// Incr simulates the Redis INCR command.
+func (r *MockRedis) Incr(key string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters[key]++
+	return r.counters[key], nil
+}
+
 // Account represents a bank account
 type Account struct {
 	AccountNumber string
@@ -84,65 +137,122 @@ type Transaction struct {
 	Amount        float64 // Positive for credit, negative for debit
 }
 
-// RedisLock represents a distributed lock
+// releaseScript atomically releases the lock only if it is still held by
+// the caller, avoiding the GET-then-DEL race where the TTL expires and a
+// new holder acquires the key between the two calls.
+const releaseScript = `if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('del', KEYS[1]) else return 0 end`
+
+// refreshScript atomically extends the lock's TTL only if it is still
+// held by the caller.
+const refreshScript = `if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('pexpire', KEYS[1], ARGV[2]) else return 0 end`
 
+// RedisLock represents a Redlock-style distributed lock scoped to a
+// single account. Each acquisition gets a fresh, cryptographically
+// random value and a monotonically increasing fencing token so callers
+// can reject writes made under a stale lock.
 type RedisLock struct {
-	client *MockRedis
-	key    string
-	value  string
+	client   *MockRedis
+	key      string
+	fenceKey string
+	value    string
 }
 
-// AcquireLock tries to acquire the lock
-func (lock *RedisLock) AcquireLock(ttl time.Duration) (bool, error) {
-	return lock.client.SetNX(lock.key, lock.value, ttl)
+// NewRedisLock creates a lock for the given account.
+func NewRedisLock(client *MockRedis, accountNumber string) *RedisLock {
+	return &RedisLock{
+		client:   client,
+		key:      fmt.Sprintf("account:%s:lock", accountNumber),
+		fenceKey: fmt.Sprintf("account:%s:fence", accountNumber),
+	}
 }
 
-// ReleaseLock releases the lock
-func (lock *RedisLock) ReleaseLock() error {
-	val, err := lock.client.Get(lock.key)
+// AcquireLock tries to acquire the lock, generating a fresh random value
+// for this holder and bumping the per-account fencing token. The
+// returned fencing token is strictly greater than any token returned by
+// a previous acquisition, so a caller can reject a write arriving from a
+// holder that has since lost the lock.
+func (lock *RedisLock) AcquireLock(ttl time.Duration) (bool, int64, error) {
+	value, err := randomLockValue()
 	if err != nil {
-		return fmt.Errorf("lock not found")
+		return false, 0, err
 	}
 
-	// Ensure the lock is released by the process that acquired it
-	if val == lock.value {
-		_, err = lock.client.Del(lock.key)
-		if err != nil {
-			return err
-		}
+	acquired, err := lock.client.SetNX(lock.key, value, ttl)
+	if err != nil {
+		return false, 0, err
 	}
-	return nil
-}
-
-// ProcessTransaction processes a single transaction on an account with distributed locking
-func ProcessTransaction(account *Account, transaction Transaction, rdb *MockRedis, wg *sync.WaitGroup) {
-	defer wg.Done()
+	if !acquired {
+		return false, 0, nil
+	}
+	lock.value = value
 
-	lock := RedisLock{
-		client: rdb,
-		key:    fmt.Sprintf("account:%s:lock", account.AccountNumber),
-		value:  fmt.Sprintf("unique-identifier-%d", time.Now().UnixNano()), // Use a unique identifier
+	fence, err := lock.client.Incr(lock.fenceKey)
+	if err != nil {
+		return false, 0, err
 	}
 
-	// Retry logic for acquiring lock
-	for i := 0; i < 3; i++ {
-		acquired, err := lock.AcquireLock(5 * time.Second)
-		if err != nil {
-			fmt.Printf("Error acquiring lock for account %s: %v\n", account.AccountNumber, err)
-			return
-		}
+	return true, fence, nil
+}
 
-		if acquired {
-			break
-		}
+// ReleaseLock releases the lock via a single atomic Lua script, so a TTL
+// expiry followed by another holder's acquisition can never cause us to
+// delete someone else's lock.
+func (lock *RedisLock) ReleaseLock() error {
+	_, err := lock.client.Eval(releaseScript, []string{lock.key}, []interface{}{lock.value})
+	return err
+}
 
-		if i == 2 {
-			fmt.Printf("Could not acquire lock for account %s after retries. Transaction skipped.\n", account.AccountNumber)
+// Refresh extends the lock's TTL on a fixed cadence for as long as ctx
+// stays alive, via the same atomic compare-and-PEXPIRE pattern used by
+// ReleaseLock. Callers should run it in its own goroutine and cancel ctx
+// once the protected work is done.
+func (lock *RedisLock) Refresh(ctx context.Context, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
 			return
+		case <-ticker.C:
+			lock.client.Eval(refreshScript, []string{lock.key}, []interface{}{lock.value, ttl.Milliseconds()})
 		}
+	}
+}
 
-		time.Sleep(100 * time.Millisecond) // Small delay before retry
+// randomLockValue generates a cryptographically random UUID used to
+// prove ownership of a lock.
+func randomLockValue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// ProcessTransaction processes a single transaction on an account with
+// distributed locking. It calls wg.Done() on the caller's WaitGroup when
+// it returns.
+func ProcessTransaction(account *Account, transaction Transaction, rdb *MockRedis, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	lock := NewRedisLock(rdb, account.AccountNumber)
+
+	acquired, fence, err := lock.AcquireLock(5 * time.Second)
+	if err != nil {
+		fmt.Printf("Error acquiring lock for account %s: %v\n", account.AccountNumber, err)
+		return
+	}
+	if !acquired {
+		fmt.Printf("Could not acquire lock for account %s. Transaction skipped.\n", account.AccountNumber)
+		return
+	}
+
+	refreshCtx, cancelRefresh := context.WithCancel(ctx)
+	go lock.Refresh(refreshCtx, 5*time.Second)
+	defer cancelRefresh()
 
 	// Lock acquired, process the transaction
 	defer lock.ReleaseLock()
@@ -153,8 +263,8 @@ func ProcessTransaction(account *Account, transaction Transaction, rdb *MockRedi
 	}
 
 	account.Balance += transaction.Amount
-	fmt.Printf("Processed transaction of %.2f on account %s. New balance: %.2f\n",
-		transaction.Amount, account.AccountNumber, account.Balance)
+	fmt.Printf("Processed transaction of %.2f on account %s. New balance: %.2f (fence %d)\n",
+		transaction.Amount, account.AccountNumber, account.Balance, fence)
 }
 
 func main() {
@@ -60,20 +60,17 @@ func TestProcessTransactionWithMockRedis(t *testing.T) {
 func TestRedisLock(t *testing.T) {
 	// Initialize mock Redis client
 	rdb := NewMockRedis()
-	lock := RedisLock{
-		client: rdb,
-		key:    "test-key",
-		value:  "test-value",
-	}
+	lock := NewRedisLock(rdb, "test-account")
 
 	// Acquire lock
-	success, err := lock.AcquireLock(1 * time.Second)
+	success, fence1, err := lock.AcquireLock(1 * time.Second)
 	if err != nil || !success {
 		t.Fatalf("Failed to acquire lock: %v", err)
 	}
 
 	// Attempt to acquire the same lock again (should fail)
-	success, err = lock.AcquireLock(1 * time.Second)
+	other := NewRedisLock(rdb, "test-account")
+	success, _, err = other.AcquireLock(1 * time.Second)
 	if err != nil {
 		t.Fatalf("Unexpected error acquiring lock: %v", err)
 	}
@@ -86,9 +83,12 @@ func TestRedisLock(t *testing.T) {
 		t.Fatalf("Failed to release lock: %v", err)
 	}
 
-	// Acquire lock again (should succeed)
-	success, err = lock.AcquireLock(1 * time.Second)
+	// Acquire lock again (should succeed), with a strictly higher fencing token
+	success, fence2, err := other.AcquireLock(1 * time.Second)
 	if err != nil || !success {
 		t.Fatalf("Failed to acquire lock after release: %v", err)
 	}
+	if fence2 <= fence1 {
+		t.Fatalf("expected fencing token to increase across acquisitions, got %d then %d", fence1, fence2)
+	}
 }
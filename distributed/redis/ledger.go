@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JournalEntry records one completed transfer so balances can be
+// reconstructed by replaying the journal.
+type JournalEntry struct {
+	ID             string
+	DebitAcct      string
+	CreditAcct     string
+	Amount         float64
+	Timestamp      time.Time
+	IdempotencyKey string
+}
+
+// Ledger coordinates atomic, multi-account transfers on top of the
+// per-account RedisLock, recording a JournalEntry for every transfer.
+type Ledger struct {
+	rdb      RedisClient
+	accounts map[string]*Account
+
+	mu      sync.Mutex
+	entries []JournalEntry
+	byKey   map[string]JournalEntry
+	pending map[string]chan struct{}
+}
+
+// NewLedger creates a Ledger backed by the given accounts and Redis client.
+func NewLedger(accounts map[string]*Account, rdb RedisClient) *Ledger {
+	return &Ledger{
+		rdb:      rdb,
+		accounts: accounts,
+		byKey:    make(map[string]JournalEntry),
+		pending:  make(map[string]chan struct{}),
+	}
+}
+
+// Transfer atomically debits fromAcct and credits toAcct by amount,
+// rejecting the whole operation if either side fails. Locks on the two
+// accounts are acquired in a deterministic order (sorted by account
+// number) so two transfers touching the same pair in opposite
+// directions cannot deadlock. A duplicate call with the same
+// idempotencyKey short-circuits and returns the original JournalEntry;
+// concurrent calls sharing a key reserve it up front, so only one of
+// them ever runs the transfer and the rest wait for its result instead
+// of racing through the debit/credit.
+func (l *Ledger) Transfer(ctx context.Context, fromAcct, toAcct string, amount float64, idempotencyKey string) (JournalEntry, error) {
+	for {
+		entry, done, wait := l.reserve(idempotencyKey)
+		if done {
+			return entry, nil
+		}
+		if wait == nil {
+			break
+		}
+		<-wait
+	}
+	defer l.release(idempotencyKey)
+
+	if err := ctx.Err(); err != nil {
+		return JournalEntry{}, err
+	}
+
+	from, exists := l.accounts[fromAcct]
+	if !exists {
+		return JournalEntry{}, fmt.Errorf("account %s not found", fromAcct)
+	}
+	to, exists := l.accounts[toAcct]
+	if !exists {
+		return JournalEntry{}, fmt.Errorf("account %s not found", toAcct)
+	}
+
+	first, second := fromAcct, toAcct
+	if second < first {
+		first, second = second, first
+	}
+
+	lock1 := NewRedisLock(l.rdb, first)
+	acquired, fence1, err := lock1.AcquireLock(5 * time.Second)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("acquiring lock for account %s: %w", first, err)
+	}
+	if !acquired {
+		return JournalEntry{}, fmt.Errorf("could not acquire lock for account %s", first)
+	}
+	defer lock1.ReleaseLock()
+
+	lock2 := NewRedisLock(l.rdb, second)
+	acquired, fence2, err := lock2.AcquireLock(5 * time.Second)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("acquiring lock for account %s: %w", second, err)
+	}
+	if !acquired {
+		return JournalEntry{}, fmt.Errorf("could not acquire lock for account %s", second)
+	}
+	defer lock2.ReleaseLock()
+
+	// Keep both locks alive for as long as the transfer takes, the same
+	// way ProcessTransaction does for a single-account transaction, so a
+	// slow transfer can't outlive its locks' TTL.
+	refreshCtx, cancelRefresh := context.WithCancel(ctx)
+	go lock1.Refresh(refreshCtx, 5*time.Second)
+	go lock2.Refresh(refreshCtx, 5*time.Second)
+	defer cancelRefresh()
+
+	if from.Balance-amount < 0 {
+		return JournalEntry{}, fmt.Errorf("insufficient funds for account %s", fromAcct)
+	}
+
+	// Re-verify both fencing tokens immediately before the write: if
+	// either lock's TTL expired mid-transfer and another caller has
+	// since acquired it, applying the mutation now would race that
+	// caller's own writes.
+	if valid, err := lock1.CheckFence(fence1); err != nil {
+		return JournalEntry{}, fmt.Errorf("verifying fence for account %s: %w", first, err)
+	} else if !valid {
+		return JournalEntry{}, fmt.Errorf("lock for account %s was lost mid-transfer (stale fencing token)", first)
+	}
+	if valid, err := lock2.CheckFence(fence2); err != nil {
+		return JournalEntry{}, fmt.Errorf("verifying fence for account %s: %w", second, err)
+	} else if !valid {
+		return JournalEntry{}, fmt.Errorf("lock for account %s was lost mid-transfer (stale fencing token)", second)
+	}
+
+	from.Balance -= amount
+	to.Balance += amount
+
+	entry := JournalEntry{
+		ID:             fmt.Sprintf("journal-%d", time.Now().UnixNano()),
+		DebitAcct:      fromAcct,
+		CreditAcct:     toAcct,
+		Amount:         amount,
+		Timestamp:      time.Now(),
+		IdempotencyKey: idempotencyKey,
+	}
+
+	l.record(entry)
+
+	return entry, nil
+}
+
+// Entries returns the recorded journal in submission order.
+func (l *Ledger) Entries() []JournalEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]JournalEntry, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+// reserve claims idempotencyKey for the calling Transfer. If a transfer
+// with that key has already completed, it returns that JournalEntry
+// with done set. If another call currently holds the key, it returns a
+// channel the caller should wait on before reserving again. Otherwise it
+// reserves the key for the caller, who must call release when done.
+func (l *Ledger) reserve(idempotencyKey string) (entry JournalEntry, done bool, wait <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.byKey[idempotencyKey]; ok {
+		return entry, true, nil
+	}
+	if inFlight, ok := l.pending[idempotencyKey]; ok {
+		return JournalEntry{}, false, inFlight
+	}
+
+	l.pending[idempotencyKey] = make(chan struct{})
+	return JournalEntry{}, false, nil
+}
+
+// release clears idempotencyKey's reservation and wakes any callers
+// waiting on it.
+func (l *Ledger) release(idempotencyKey string) {
+	l.mu.Lock()
+	wait, ok := l.pending[idempotencyKey]
+	delete(l.pending, idempotencyKey)
+	l.mu.Unlock()
+
+	if ok {
+		close(wait)
+	}
+}
+
+// record appends entry to the journal and makes it visible to
+// subsequent reserve calls under the same idempotency key.
+func (l *Ledger) record(entry JournalEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry)
+	l.byKey[entry.IdempotencyKey] = entry
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestLedgerTransfer(t *testing.T) {
+	rdb := NewMockRedisClient()
+	accounts := map[string]*Account{
+		"11111": {AccountNumber: "11111", Balance: 1000},
+		"22222": {AccountNumber: "22222", Balance: 2000},
+	}
+	ledger := NewLedger(accounts, rdb)
+
+	entry, err := ledger.Transfer(context.Background(), "22222", "11111", 500, "idem-1")
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	if accounts["22222"].Balance != 1500 || accounts["11111"].Balance != 1500 {
+		t.Fatalf("unexpected balances after transfer: from=%.2f to=%.2f", accounts["22222"].Balance, accounts["11111"].Balance)
+	}
+
+	if entry.DebitAcct != "22222" || entry.CreditAcct != "11111" || entry.Amount != 500 {
+		t.Fatalf("unexpected journal entry: %+v", entry)
+	}
+
+	// Resubmitting with the same idempotency key must not double-apply.
+	again, err := ledger.Transfer(context.Background(), "22222", "11111", 500, "idem-1")
+	if err != nil {
+		t.Fatalf("Transfer (duplicate) failed: %v", err)
+	}
+	if again.ID != entry.ID {
+		t.Fatalf("expected duplicate submission to return original entry, got a new one")
+	}
+	if accounts["22222"].Balance != 1500 || accounts["11111"].Balance != 1500 {
+		t.Fatalf("duplicate idempotency key must not re-apply the transfer")
+	}
+}
+
+func TestLedgerTransferConcurrentSameKeyAppliesOnce(t *testing.T) {
+	rdb := NewMockRedisClient()
+	accounts := map[string]*Account{
+		"11111": {AccountNumber: "11111", Balance: 1000},
+		"22222": {AccountNumber: "22222", Balance: 2000},
+	}
+	ledger := NewLedger(accounts, rdb)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	ids := make([]string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry, err := ledger.Transfer(context.Background(), "11111", "22222", 100, "same-key")
+			if err != nil {
+				t.Errorf("Transfer failed: %v", err)
+				return
+			}
+			ids[i] = entry.ID
+		}(i)
+	}
+	wg.Wait()
+
+	if accounts["11111"].Balance != 900 {
+		t.Fatalf("expected the transfer to apply exactly once, balance got debited to %.2f", accounts["11111"].Balance)
+	}
+	for _, id := range ids {
+		if id != ids[0] {
+			t.Fatalf("expected all concurrent callers to observe the same journal entry, got %q and %q", ids[0], id)
+		}
+	}
+}
+
+func TestLedgerTransferInsufficientFunds(t *testing.T) {
+	rdb := NewMockRedisClient()
+	accounts := map[string]*Account{
+		"11111": {AccountNumber: "11111", Balance: 100},
+		"22222": {AccountNumber: "22222", Balance: 2000},
+	}
+	ledger := NewLedger(accounts, rdb)
+
+	if _, err := ledger.Transfer(context.Background(), "11111", "22222", 500, "idem-2"); err == nil {
+		t.Fatalf("expected insufficient funds error")
+	}
+	if accounts["11111"].Balance != 100 || accounts["22222"].Balance != 2000 {
+		t.Fatalf("failed transfer must leave balances unchanged")
+	}
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+
+	"horten/observability"
 )
 
 var ctx = context.Background()
@@ -23,11 +27,33 @@ type Transaction struct {
 	Amount        float64 // Positive for credit, negative for debit
 }
 
-// RedisLock represents a distributed lock
+// releaseScript atomically releases the lock only if it is still held by
+// the caller, avoiding the GET-then-DEL race where the TTL expires and a
+// new holder acquires the key between the two calls.
+const releaseScript = `if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('del', KEYS[1]) else return 0 end`
+
+// refreshScript atomically extends the lock's TTL only if it is still
+// held by the caller.
+const refreshScript = `if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('pexpire', KEYS[1], ARGV[2]) else return 0 end`
+
+// RedisLock represents a Redlock-style distributed lock scoped to a
+// single account. Each acquisition gets a fresh, cryptographically
+// random value and a monotonically increasing fencing token so callers
+// can reject writes made under a stale lock.
 type RedisLock struct {
-	client RedisClient
-	key    string
-	value  string
+	client   RedisClient
+	key      string
+	fenceKey string
+	value    string
+}
+
+// NewRedisLock creates a lock for the given account.
+func NewRedisLock(client RedisClient, accountNumber string) *RedisLock {
+	return &RedisLock{
+		client:   client,
+		key:      fmt.Sprintf("account:%s:lock", accountNumber),
+		fenceKey: fmt.Sprintf("account:%s:fence", accountNumber),
+	}
 }
 
 // RedisClient defines the interface for a Redis client
@@ -35,6 +61,8 @@ type RedisClient interface {
 	SetNX(key, value string, ttl time.Duration) (bool, error)
 	Get(key string) (string, error)
 	Del(key string) (int64, error)
+	Eval(script string, keys []string, args []interface{}) (interface{}, error)
+	Incr(key string) (int64, error)
 }
 
 // RedisAdapter wraps *redis.Client to implement RedisClient
@@ -57,67 +85,160 @@ func (r *RedisAdapter) Del(key string) (int64, error) {
 	return result, err
 }
 
-// AcquireLock tries to acquire the lock
-func (lock *RedisLock) AcquireLock(ttl time.Duration) (bool, error) {
-	success, err := lock.client.SetNX(lock.key, lock.value, ttl)
+func (r *RedisAdapter) Eval(script string, keys []string, args []interface{}) (interface{}, error) {
+	return r.client.Eval(ctx, script, keys, args...).Result()
+}
+
+func (r *RedisAdapter) Incr(key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// AcquireLock tries to acquire the lock, generating a fresh random value
+// for this holder and bumping the per-account fencing token. The
+// returned fencing token is strictly greater than any token returned by
+// a previous acquisition, so a caller can reject a write arriving from a
+// holder that has since lost the lock.
+func (lock *RedisLock) AcquireLock(ttl time.Duration) (bool, int64, error) {
+	start := time.Now()
+
+	value, err := randomLockValue()
+	if err != nil {
+		observability.Default().IncLockAcquireFailure()
+		return false, 0, err
+	}
+
+	acquired, err := lock.client.SetNX(lock.key, value, ttl)
+	if err != nil {
+		observability.Default().IncLockAcquireFailure()
+		observability.Default().ObserveLockWait(time.Since(start))
+		return false, 0, err
+	}
+	if !acquired {
+		observability.Default().IncLockAcquireFailure()
+		observability.Default().ObserveLockWait(time.Since(start))
+		return false, 0, nil
+	}
+	lock.value = value
+
+	fence, err := lock.client.Incr(lock.fenceKey)
+	if err != nil {
+		observability.Default().ObserveLockWait(time.Since(start))
+		return false, 0, err
+	}
+
+	observability.Default().ObserveLockWait(time.Since(start))
+	return true, fence, nil
+}
+
+// CheckFence reports whether fence, the token returned by a prior
+// AcquireLock, is still the most recently issued one for this lock's
+// account. A mismatch means the lock's TTL expired and another caller
+// has since acquired (and bumped the fencing counter), so the original
+// holder's pending write must be rejected rather than applied.
+func (lock *RedisLock) CheckFence(fence int64) (bool, error) {
+	current, err := lock.client.Get(lock.fenceKey)
 	if err != nil {
 		return false, err
 	}
-	return success, nil
+	currentFence, err := strconv.ParseInt(current, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("parsing fence token for %s: %w", lock.fenceKey, err)
+	}
+	return currentFence == fence, nil
 }
 
-// ReleaseLock releases the lock
+// ReleaseLock releases the lock via a single atomic Lua script, so a TTL
+// expiry followed by another holder's acquisition can never cause us to
+// delete someone else's lock.
 func (lock *RedisLock) ReleaseLock() error {
-	val, err := lock.client.Get(lock.key)
-	if err == redis.Nil {
-		return fmt.Errorf("lock not found")
-	} else if err != nil {
-		return err
-	}
-
-	// Ensure the lock is released by the process that acquired it
-	if val == lock.value {
-		_, err = lock.client.Del(lock.key)
-		if err != nil {
-			return err
+	_, err := lock.client.Eval(releaseScript, []string{lock.key}, []interface{}{lock.value})
+	return err
+}
+
+// Refresh extends the lock's TTL on a fixed cadence for as long as ctx
+// stays alive, via the same atomic compare-and-PEXPIRE pattern used by
+// ReleaseLock. Callers should run it in its own goroutine and cancel ctx
+// once the protected work is done.
+func (lock *RedisLock) Refresh(ctx context.Context, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lock.client.Eval(refreshScript, []string{lock.key}, []interface{}{lock.value, ttl.Milliseconds()})
 		}
 	}
-	return nil
 }
 
-// ProcessTransaction processes a single transaction on an account with distributed locking
-func ProcessTransaction(account *Account, transaction Transaction, rdb RedisClient, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	lock := RedisLock{
-		client: rdb,
-		key:    fmt.Sprintf("account:%s:lock", account.AccountNumber),
-		value:  "unique-identifier", // Use a UUID in production
+// randomLockValue generates a cryptographically random UUID used to
+// prove ownership of a lock.
+func randomLockValue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// ProcessTransaction processes a single transaction on an account with
+// distributed locking. The caller owns the WaitGroup it launches this
+// under and is responsible for calling wg.Done().
+func ProcessTransaction(account *Account, transaction Transaction, rdb RedisClient) {
+	start := time.Now()
+	logger := observability.Logger(ctx)
+
+	lock := NewRedisLock(rdb, account.AccountNumber)
 
 	// Try to acquire the lock
-	acquired, err := lock.AcquireLock(5 * time.Second)
+	acquired, fence, err := lock.AcquireLock(5 * time.Second)
 	if err != nil {
-		fmt.Printf("Error acquiring lock for account %s: %v\n", account.AccountNumber, err)
+		observability.Default().ObserveTransaction("lock_timeout", time.Since(start))
+		logger.Error("acquiring lock", "account_number", account.AccountNumber, "error", err)
 		return
 	}
 
 	if !acquired {
-		fmt.Printf("Could not acquire lock for account %s. Transaction skipped.\n", account.AccountNumber)
+		observability.Default().ObserveTransaction("lock_timeout", time.Since(start))
+		logger.Warn("could not acquire lock, transaction skipped", "account_number", account.AccountNumber)
 		return
 	}
 
+	refreshCtx, cancelRefresh := context.WithCancel(ctx)
+	go lock.Refresh(refreshCtx, 5*time.Second)
+	defer cancelRefresh()
+
 	// Lock acquired, process the transaction
 	defer lock.ReleaseLock()
 
 	if transaction.Amount < 0 && account.Balance+transaction.Amount < 0 {
-		fmt.Printf("Insufficient funds for account %s. Transaction skipped.\n", account.AccountNumber)
+		observability.Default().ObserveTransaction("insufficient", time.Since(start))
+		logger.Warn("insufficient funds, transaction skipped", "account_number", account.AccountNumber, "amount", transaction.Amount)
+		return
+	}
+
+	// Re-verify the fencing token immediately before the write: if the
+	// lock's TTL expired while this call was running and another holder
+	// has since acquired it (bumping the fence), applying the mutation
+	// now would race that holder's own writes.
+	if valid, err := lock.CheckFence(fence); err != nil {
+		observability.Default().ObserveTransaction("lock_timeout", time.Since(start))
+		logger.Error("verifying fence", "account_number", account.AccountNumber, "error", err)
+		return
+	} else if !valid {
+		observability.Default().ObserveTransaction("lock_timeout", time.Since(start))
+		logger.Warn("stale fencing token, transaction skipped", "account_number", account.AccountNumber, "fence", fence)
 		return
 	}
 
 	account.Balance += transaction.Amount
-	fmt.Printf("Processed transaction of %.2f on account %s. New balance: %.2f\n",
-		transaction.Amount, account.AccountNumber, account.Balance)
+	observability.Default().ObserveTransaction("ok", time.Since(start))
+	observability.Default().SetAccountBalance(account.AccountNumber, account.Balance)
+	logger.Info("transaction processed", "account_number", account.AccountNumber, "amount", transaction.Amount, "fence", fence, "balance", account.Balance)
 }
 
 func main() {
@@ -156,7 +277,10 @@ func main() {
 		}
 
 		wg.Add(1)
-		go ProcessTransaction(account, t, rdb, &wg)
+		go func(account *Account, t Transaction) {
+			defer wg.Done()
+			ProcessTransaction(account, t, rdb)
+		}(account, t)
 	}
 
 	wg.Wait() // Wait for all transactions to complete
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -8,12 +10,16 @@ import (
 
 // MockRedisClient simulates a Redis client for testing
 type MockRedisClient struct {
-	locks map[string]string
-	mu    sync.Mutex
+	locks    map[string]string
+	counters map[string]int64
+	mu       sync.Mutex
 }
 
 func NewMockRedisClient() *MockRedisClient {
-	return &MockRedisClient{locks: make(map[string]string)}
+	return &MockRedisClient{
+		locks:    make(map[string]string),
+		counters: make(map[string]int64),
+	}
 }
 
 func (m *MockRedisClient) SetNX(key, value string, ttl time.Duration) (bool, error) {
@@ -37,15 +43,20 @@ func (m *MockRedisClient) SetNX(key, value string, ttl time.Duration) (bool, err
 	return true, nil
 }
 
+// Get reads a lock's value, falling back to a counter's value (as real
+// Redis would, since SetNX/Incr/Get all share one keyspace there) so
+// RedisLock.CheckFence can read a fencing token back with a plain Get.
 func (m *MockRedisClient) Get(key string) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	value, exists := m.locks[key]
-	if !exists {
-		return "", nil
+	if value, exists := m.locks[key]; exists {
+		return value, nil
+	}
+	if counter, exists := m.counters[key]; exists {
+		return strconv.FormatInt(counter, 10), nil
 	}
-	return value, nil
+	return "", nil
 }
 
 func (m *MockRedisClient) Del(key string) (int64, error) {
@@ -59,6 +70,65 @@ func (m *MockRedisClient) Del(key string) (int64, error) {
 	return 0, nil
 }
 
+func (m *MockRedisClient) Eval(script string, keys []string, args []interface{}) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, value := keys[0], args[0].(string)
+
+	switch script {
+	case releaseScript:
+		if m.locks[key] == value {
+			delete(m.locks, key)
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case refreshScript:
+		if m.locks[key] != value {
+			return int64(0), nil
+		}
+		return int64(1), nil
+	default:
+		return nil, fmt.Errorf("unsupported script")
+	}
+}
+
+func (m *MockRedisClient) Incr(key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counters[key]++
+	return m.counters[key], nil
+}
+
+func TestRedisLockCheckFenceDetectsStaleLock(t *testing.T) {
+	rdb := NewMockRedisClient()
+
+	original := NewRedisLock(rdb, "11111")
+	acquired, fence, err := original.AcquireLock(time.Millisecond)
+	if err != nil || !acquired {
+		t.Fatalf("failed to acquire original lock: %v", err)
+	}
+
+	// Simulate the original lock's TTL expiring and a new holder taking
+	// over (bumping the fencing counter) while the original caller is
+	// still mid-transaction and unaware it lost the lock.
+	time.Sleep(5 * time.Millisecond)
+	successor := NewRedisLock(rdb, "11111")
+	acquired, _, err = successor.AcquireLock(5 * time.Second)
+	if err != nil || !acquired {
+		t.Fatalf("successor failed to acquire lock after expiry: %v", err)
+	}
+
+	valid, err := original.CheckFence(fence)
+	if err != nil {
+		t.Fatalf("CheckFence failed: %v", err)
+	}
+	if valid {
+		t.Fatalf("expected the original holder's fencing token to be stale once a successor acquired the lock")
+	}
+}
+
 func TestProcessTransactionWithMockRedis(t *testing.T) {
 	// Initialize mock Redis client
 	rdb := NewMockRedisClient()
@@ -97,7 +167,7 @@ func TestProcessTransactionWithMockRedis(t *testing.T) {
 		wg.Add(1)
 		go func(acc *Account, tr Transaction) {
 			defer wg.Done()
-			ProcessTransaction(acc, tr, rdb, &wg)
+			ProcessTransaction(acc, tr, rdb)
 		}(account, tr)
 	}
 
@@ -1,33 +1,40 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
-	"horten/service"
-	"log"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"horten/observability"
+	"horten/service"
 )
 
 // Handler wraps dependencies for HTTP handlers.
 type Handler struct {
 	accountService *service.AccountService
+	ledger         *service.Ledger
 }
 
 // NewHandler creates a new Handler.
-func NewHandler(accountService *service.AccountService) *Handler {
-	return &Handler{accountService: accountService}
+func NewHandler(accountService *service.AccountService, ledger *service.Ledger) *Handler {
+	return &Handler{accountService: accountService, ledger: ledger}
 }
 
 // GetAccountBalanceHandler handles requests for account balances.
 func (h *Handler) GetAccountBalanceHandler(w http.ResponseWriter, r *http.Request) {
 	accountNumber := r.URL.Query().Get("accountNumber")
 	if accountNumber == "" {
-		http.Error(w, "accountNumber is required", http.StatusBadRequest)
+		writeProblem(w, r, "invalid_request", "accountNumber is required")
 		return
 	}
 
-	account, err := h.accountService.GetBalance(accountNumber)
+	account, err := h.accountService.GetBalance(r.Context(), accountNumber)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeAccountError(w, r, err)
 		return
 	}
 
@@ -37,10 +44,104 @@ func (h *Handler) GetAccountBalanceHandler(w http.ResponseWriter, r *http.Reques
 	w.Write(response)
 }
 
-// Logger middleware for logging HTTP requests.
+// transferRequest is the body of POST /account/transfer.
+type transferRequest struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// TransferHandler handles POST /account/transfer, debiting From and
+// crediting To by Amount under the idempotency key carried in the
+// Idempotency-Key header.
+func (h *Handler) TransferHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, "invalid_request", "method not allowed")
+		return
+	}
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		writeProblem(w, r, "invalid_request", "Idempotency-Key header is required")
+		return
+	}
+
+	var req transferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, "invalid_request", "malformed JSON body")
+		return
+	}
+	if req.From == "" || req.To == "" || req.Amount <= 0 {
+		writeProblem(w, r, "invalid_request", "from, to and a positive amount are required")
+		return
+	}
+
+	entry, err := h.ledger.Transfer(r.Context(), req.From, req.To, req.Amount, idempotencyKey)
+	if err != nil {
+		writeAccountError(w, r, err)
+		return
+	}
+
+	response, _ := json.Marshal(entry)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// TransactionsHandler handles GET /account/{n}/transactions?since=...,
+// streaming the account's journal entries as newline-delimited JSON.
+func (h *Handler) TransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	accountNumber := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/account/"), "/transactions")
+	if accountNumber == "" || strings.Contains(accountNumber, "/") {
+		writeProblem(w, r, "invalid_request", "account number is required")
+		return
+	}
+
+	since := time.Time{}
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		seconds, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			writeProblem(w, r, "invalid_request", "since must be a unix timestamp")
+			return
+		}
+		since = time.Unix(seconds, 0)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for _, entry := range h.ledger.EntriesSince(accountNumber, since) {
+		if err := encoder.Encode(entry); err != nil {
+			observability.Logger(r.Context()).Error("encoding journal entry", "error", err)
+			return
+		}
+	}
+}
+
+// writeAccountError maps an error returned by AccountService or Ledger
+// to the problem+json category its clients should branch on.
+func writeAccountError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound):
+		writeProblem(w, r, "account_not_found", err.Error())
+	case errors.Is(err, service.ErrInsufficientFunds):
+		writeProblem(w, r, "insufficient_funds", err.Error())
+	case errors.Is(err, service.ErrIdempotencyConflict):
+		writeProblem(w, r, "idempotency_conflict", err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		writeProblem(w, r, "lock_timeout", err.Error())
+	default:
+		writeProblem(w, r, "internal_error", err.Error())
+	}
+}
+
+// Logger middleware logs each HTTP request, tagged with its correlation
+// ID if RequestID has attached one to the request's context.
 func (h *Handler) Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s %s %s", r.Method, r.RequestURI, r.RemoteAddr)
+		observability.Logger(r.Context()).Info("http request", "method", r.Method, "uri", r.RequestURI, "remote_addr", r.RemoteAddr)
 		next.ServeHTTP(w, r)
 	})
 }
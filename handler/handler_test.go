@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"horten/service"
+)
+
+func newTestHandler() *Handler {
+	repo := service.NewMemoryRepository(service.DefaultAccounts)
+	accountService := service.NewAccountService(repo)
+	ledger := service.NewLedger(repo)
+	return NewHandler(accountService, ledger)
+}
+
+func TestTransferHandler(t *testing.T) {
+	h := newTestHandler()
+
+	body := strings.NewReader(`{"from":"123456","to":"654321","amount":100,"currency":"USD"}`)
+	req := httptest.NewRequest(http.MethodPost, "/account/transfer", body)
+	req.Header.Set("Idempotency-Key", "key-1")
+	rr := httptest.NewRecorder()
+
+	h.TransferHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var entry service.JournalEntry
+	if err := json.Unmarshal(rr.Body.Bytes(), &entry); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if entry.DebitAcct != "123456" || entry.CreditAcct != "654321" || entry.Amount != 100 {
+		t.Fatalf("unexpected journal entry: %+v", entry)
+	}
+}
+
+func TestTransferHandlerMissingIdempotencyKey(t *testing.T) {
+	h := newTestHandler()
+
+	body := strings.NewReader(`{"from":"123456","to":"654321","amount":100,"currency":"USD"}`)
+	req := httptest.NewRequest(http.MethodPost, "/account/transfer", body)
+	rr := httptest.NewRecorder()
+
+	h.TransferHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected problem+json content type, got %q", ct)
+	}
+}
+
+func TestTransferHandlerMalformedBody(t *testing.T) {
+	h := newTestHandler()
+
+	body := strings.NewReader(`{"from": "123456", "to": `)
+	req := httptest.NewRequest(http.MethodPost, "/account/transfer", body)
+	req.Header.Set("Idempotency-Key", "key-1")
+	rr := httptest.NewRecorder()
+
+	h.TransferHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decoding problem response: %v", err)
+	}
+	if problem.Title != "Invalid Request" {
+		t.Fatalf("unexpected problem title: %q", problem.Title)
+	}
+}
+
+func TestTransactionsHandlerParsesSince(t *testing.T) {
+	h := newTestHandler()
+
+	if _, err := h.ledger.Transfer(context.Background(), "123456", "654321", 100, "key-1"); err != nil {
+		t.Fatalf("seeding transfer failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/account/123456/transactions?since=0", nil)
+	rr := httptest.NewRecorder()
+
+	h.TransactionsHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var entry service.JournalEntry
+	if err := json.Unmarshal(rr.Body.Bytes(), &entry); err != nil {
+		t.Fatalf("decoding ndjson entry: %v (body: %q)", err, rr.Body.String())
+	}
+	if entry.DebitAcct != "123456" || entry.CreditAcct != "654321" {
+		t.Fatalf("unexpected journal entry: %+v", entry)
+	}
+}
+
+func TestTransactionsHandlerInvalidSince(t *testing.T) {
+	h := newTestHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/account/123456/transactions?since=not-a-number", nil)
+	rr := httptest.NewRecorder()
+
+	h.TransactionsHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestRecoveryMiddlewareConvertsPanicToProblem(t *testing.T) {
+	h := newTestHandler()
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/account/balance", nil)
+	rr := httptest.NewRecorder()
+
+	h.Recovery(panicking).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected problem+json content type, got %q", ct)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decoding problem response: %v", err)
+	}
+	if problem.Status != http.StatusInternalServerError {
+		t.Fatalf("unexpected problem status: %d", problem.Status)
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"horten/observability"
+)
+
+// RequestID middleware generates a random request ID for every
+// request (or reuses one supplied by an upstream proxy), propagates it
+// via context as the correlation ID the service and ledger layers log
+// with, and echoes it back in the X-Request-Id response header.
+func (h *Handler) RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if id == "" {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-Id", id)
+		ctx := observability.WithCorrelationID(r.Context(), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%x", buf)
+}
+
+// Auth middleware rejects requests that don't carry the configured
+// bearer token in their Authorization header.
+func (h *Handler) Auth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				writeProblem(w, r, "invalid_request", "missing or invalid Authorization header")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Recovery middleware converts a panic in a downstream handler into an
+// internal_error problem+json response instead of crashing the server.
+func (h *Handler) Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				writeProblem(w, r, "internal_error", fmt.Sprintf("panic: %v", rec))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
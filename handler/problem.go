@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Problem is an RFC 7807 application/problem+json error response.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+// problemCategory describes the status and title a category of error
+// is reported under.
+type problemCategory struct {
+	status int
+	title  string
+}
+
+// problemCategories maps the error categories clients can branch on to
+// the HTTP status and title their problem+json response carries.
+var problemCategories = map[string]problemCategory{
+	"invalid_request":      {http.StatusBadRequest, "Invalid Request"},
+	"account_not_found":    {http.StatusNotFound, "Account Not Found"},
+	"insufficient_funds":   {http.StatusConflict, "Insufficient Funds"},
+	"idempotency_conflict": {http.StatusConflict, "Idempotency Key Conflict"},
+	"lock_timeout":         {http.StatusServiceUnavailable, "Lock Timeout"},
+	"internal_error":       {http.StatusInternalServerError, "Internal Server Error"},
+}
+
+// writeProblem writes an application/problem+json response for the
+// given category, looking up its status and title from
+// problemCategories. Unknown categories fall back to internal_error.
+func writeProblem(w http.ResponseWriter, r *http.Request, category, detail string) {
+	cat, ok := problemCategories[category]
+	if !ok {
+		cat = problemCategories["internal_error"]
+		category = "internal_error"
+	}
+
+	problem := Problem{
+		Type:     "https://horten.dev/problems/" + category,
+		Title:    cat.title,
+		Status:   cat.status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	}
+
+	body, err := json.Marshal(problem)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(cat.status)
+	w.Write(body)
+}
@@ -7,30 +7,70 @@ import (
 	"horten/config"
 	"horten/handler"
 	"horten/service"
+	"horten/watcher"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	//"github.com/go-delve/delve/pkg/config"
 )
 
+// settlementAccounts maps each asset the deposit watcher settles to the
+// account its incoming deposits are funded from.
+var settlementAccounts = map[string]string{
+	"USD": "654321",
+}
+
 func main() {
 	// Load configuration
 	config := config.LoadConfig()
 
 	// Initialize services
-	accountService := service.NewAccountService()
-	handler := handler.NewHandler(accountService)
+	repo := service.NewMemoryRepository(service.DefaultAccounts)
+	accountService := service.NewAccountService(repo)
+	ledger := service.NewLedger(repo)
+	handler := handler.NewHandler(accountService, ledger)
+
+	// Chain applies the standard middleware stack, innermost first:
+	// recovery so a panic in the handler still gets a response,
+	// then request logging, then auth, then request-ID outermost so
+	// every layer below it sees the correlation ID.
+	chain := func(h http.HandlerFunc) http.Handler {
+		return handler.RequestID(handler.Auth(config.AuthToken)(handler.Logger(handler.Recovery(h))))
+	}
 
 	// Create HTTP server
-	http.Handle("/account/balance", handler.Logger(http.HandlerFunc(handler.GetAccountBalanceHandler)))
+	http.Handle("/account/balance", chain(handler.GetAccountBalanceHandler))
+	http.Handle("/account/transfer", chain(handler.TransferHandler))
+	http.Handle("/account/", chain(handler.TransactionsHandler))
+	http.Handle("/metrics", promhttp.Handler())
 
 	server := &http.Server{
 		Addr: fmt.Sprintf(":%s", config.Port),
 	}
 
+	// Deposit watcher: auto-transfers incoming deposits to their target
+	// accounts from the configured settlement accounts.
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379", // Adjust based on your setup
+		DB:   0,
+	})
+	depositWatcher := watcher.NewDepositWatcher(
+		watcher.NewRedisDepositSource(redisClient),
+		ledger,
+		watcher.NewRedisLastSeenStore(redisClient),
+		settlementAccounts,
+		10*time.Second,
+	)
+
+	watcherCtx, cancelWatcher := context.WithCancel(context.Background())
+	go depositWatcher.Run(watcherCtx)
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -45,6 +85,10 @@ func main() {
 	<-quit
 	log.Println("Shutting down server...")
 
+	cancelWatcher()
+	depositWatcher.Wait()
+	log.Println("Deposit watcher drained")
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
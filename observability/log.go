@@ -0,0 +1,34 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+)
+
+// correlationIDKey is the context key a request's correlation ID is
+// stored under, so it can ride along through the service and ledger
+// layers and end up on every log line they emit for that request.
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context carrying id, retrievable with
+// CorrelationID or Logger.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID stashed by WithCorrelationID,
+// or "" if none is present.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// Logger returns the default slog.Logger annotated with the correlation
+// ID carried by ctx, if any.
+func Logger(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if id := CorrelationID(ctx); id != "" {
+		logger = logger.With("correlation_id", id)
+	}
+	return logger
+}
@@ -0,0 +1,91 @@
+// Package observability provides the Prometheus metrics and
+// correlation-ID-aware logging shared by transaction processing across
+// the module's packages.
+package observability
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics holds the Prometheus collectors instrumenting transaction
+// processing: how transactions resolve and how long they take, how
+// distributed lock acquisition behaves, and the current balance of
+// every account touched.
+type Metrics struct {
+	TransactionsTotal   *prometheus.CounterVec
+	LockAcquireFailures prometheus.Counter
+	TransactionDuration prometheus.Histogram
+	LockWaitSeconds     prometheus.Histogram
+	AccountBalance      *prometheus.GaugeVec
+}
+
+// NewMetrics creates and registers a Metrics against reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	factory := promauto.With(reg)
+
+	return &Metrics{
+		TransactionsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "transactions_total",
+			Help: "Total number of processed transactions, by result.",
+		}, []string{"result"}),
+		LockAcquireFailures: factory.NewCounter(prometheus.CounterOpts{
+			Name: "lock_acquire_failures_total",
+			Help: "Total number of failed distributed lock acquisitions.",
+		}),
+		TransactionDuration: factory.NewHistogram(prometheus.HistogramOpts{
+			Name: "transaction_duration_seconds",
+			Help: "Time to process a transaction end-to-end.",
+		}),
+		LockWaitSeconds: factory.NewHistogram(prometheus.HistogramOpts{
+			Name: "lock_wait_seconds",
+			Help: "Time spent attempting to acquire a distributed lock.",
+		}),
+		AccountBalance: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "account_balance",
+			Help: "Current balance of an account.",
+		}, []string{"account"}),
+	}
+}
+
+var (
+	defaultMetrics     *Metrics
+	defaultMetricsOnce sync.Once
+)
+
+// Default returns the process-wide Metrics registered against
+// prometheus.DefaultRegisterer, creating it on first use so every
+// package that instruments itself shares the same collectors instead of
+// each registering its own (and panicking on the duplicate names).
+func Default() *Metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetrics = NewMetrics(prometheus.DefaultRegisterer)
+	})
+	return defaultMetrics
+}
+
+// ObserveTransaction records the outcome and duration of a processed
+// transaction.
+func (m *Metrics) ObserveTransaction(result string, duration time.Duration) {
+	m.TransactionsTotal.WithLabelValues(result).Inc()
+	m.TransactionDuration.Observe(duration.Seconds())
+}
+
+// ObserveLockWait records how long a lock acquisition attempt took,
+// successful or not.
+func (m *Metrics) ObserveLockWait(duration time.Duration) {
+	m.LockWaitSeconds.Observe(duration.Seconds())
+}
+
+// IncLockAcquireFailure records a failed (or contended) lock acquisition.
+func (m *Metrics) IncLockAcquireFailure() {
+	m.LockAcquireFailures.Inc()
+}
+
+// SetAccountBalance records account's current balance.
+func (m *Metrics) SetAccountBalance(account string, balance float64) {
+	m.AccountBalance.WithLabelValues(account).Set(balance)
+}
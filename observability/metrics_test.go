@@ -0,0 +1,52 @@
+package observability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMetricsObserveTransaction(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.ObserveTransaction("ok", 0)
+	m.ObserveTransaction("insufficient", 0)
+
+	metric := &dto.Metric{}
+	if err := m.TransactionsTotal.WithLabelValues("ok").Write(metric); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if metric.Counter.GetValue() != 1 {
+		t.Fatalf("expected 1 ok transaction, got %v", metric.Counter.GetValue())
+	}
+}
+
+func TestMetricsSetAccountBalance(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	m.SetAccountBalance("123456", 850.0)
+
+	metric := &dto.Metric{}
+	if err := m.AccountBalance.WithLabelValues("123456").Write(metric); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if metric.Gauge.GetValue() != 850.0 {
+		t.Fatalf("expected balance 850.0, got %v", metric.Gauge.GetValue())
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	ctx := context.Background()
+	if got := CorrelationID(ctx); got != "" {
+		t.Fatalf("expected empty correlation ID, got %q", got)
+	}
+
+	ctx = WithCorrelationID(ctx, "req-1")
+	if got := CorrelationID(ctx); got != "req-1" {
+		t.Fatalf("expected correlation ID req-1, got %q", got)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"horten/observability"
+)
+
+// ErrIdempotencyConflict is returned by Ledger.Transfer when an
+// idempotency key is reused with a different from/to/amount than the
+// transfer it was first submitted with.
+var ErrIdempotencyConflict = fmt.Errorf("idempotency key conflict")
+
+// JournalEntry records one completed transfer through a Ledger, so
+// balances can be reconstructed by replaying the journal.
+type JournalEntry struct {
+	ID             string
+	DebitAcct      string
+	CreditAcct     string
+	Amount         float64
+	Timestamp      time.Time
+	IdempotencyKey string
+}
+
+// Ledger performs atomic multi-account transfers on top of a
+// Repository, recording a JournalEntry for every transfer. A duplicate
+// call with the same idempotencyKey short-circuits and returns the
+// original JournalEntry; reusing the key with different transfer
+// details is rejected with ErrIdempotencyConflict.
+type Ledger struct {
+	repo Repository
+
+	mu      sync.Mutex
+	entries []JournalEntry
+	byKey   map[string]JournalEntry
+	pending map[string]chan struct{}
+}
+
+// NewLedger creates a Ledger backed by repo.
+func NewLedger(repo Repository) *Ledger {
+	return &Ledger{
+		repo:    repo,
+		byKey:   make(map[string]JournalEntry),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+// Transfer atomically debits fromAcct and credits toAcct by amount
+// within a single Repository transaction, rejecting the whole operation
+// if either account is unknown or the debit would overdraw fromAcct. A
+// duplicate call with the same idempotencyKey short-circuits and
+// returns the original JournalEntry; concurrent calls sharing a key
+// reserve it up front, so only one of them ever runs the transfer and
+// the rest wait for its result instead of racing through the debit and
+// credit.
+func (l *Ledger) Transfer(ctx context.Context, fromAcct, toAcct string, amount float64, idempotencyKey string) (JournalEntry, error) {
+	start := time.Now()
+
+	var entry JournalEntry
+	for {
+		var done bool
+		var wait <-chan struct{}
+		entry, done, wait = l.reserve(idempotencyKey)
+		if done {
+			if entry.DebitAcct != fromAcct || entry.CreditAcct != toAcct || entry.Amount != amount {
+				return JournalEntry{}, fmt.Errorf("idempotency key %s: %w", idempotencyKey, ErrIdempotencyConflict)
+			}
+			return entry, nil
+		}
+		if wait == nil {
+			break
+		}
+		<-wait
+	}
+	defer l.release(idempotencyKey)
+
+	if err := ctx.Err(); err != nil {
+		observability.Default().ObserveTransaction("lock_timeout", time.Since(start))
+		return JournalEntry{}, err
+	}
+
+	var fromBalance, toBalance float64
+	err := l.repo.WithTx(func(tx Tx) error {
+		// Read the accounts in sorted order, not caller-supplied order, so
+		// two transfers touching the same pair in opposite directions
+		// can't deadlock on the Postgres backend's FOR UPDATE row locks.
+		first, second := fromAcct, toAcct
+		if second < first {
+			first, second = second, first
+		}
+		accts := make(map[string]Account, 2)
+		for _, acctNum := range []string{first, second} {
+			acct, err := tx.GetAccount(acctNum)
+			if err != nil {
+				return err
+			}
+			accts[acctNum] = acct
+		}
+		from, to := accts[fromAcct], accts[toAcct]
+		if from.Balance-amount < 0 {
+			return fmt.Errorf("account %s: %w", fromAcct, ErrInsufficientFunds)
+		}
+
+		fromBalance = from.Balance - amount
+		toBalance = to.Balance + amount
+		if err := tx.UpdateBalance(fromAcct, fromBalance); err != nil {
+			return err
+		}
+		if err := tx.UpdateBalance(toAcct, toBalance); err != nil {
+			return err
+		}
+
+		entry = JournalEntry{
+			ID:             fmt.Sprintf("journal-%d", time.Now().UnixNano()),
+			DebitAcct:      fromAcct,
+			CreditAcct:     toAcct,
+			Amount:         amount,
+			Timestamp:      time.Now(),
+			IdempotencyKey: idempotencyKey,
+		}
+		return nil
+	})
+	if err != nil {
+		result := "error"
+		if errors.Is(err, ErrInsufficientFunds) {
+			result = "insufficient"
+		}
+		observability.Default().ObserveTransaction(result, time.Since(start))
+		observability.Logger(ctx).Error("transfer failed", "from_account", fromAcct, "to_account", toAcct, "amount", amount, "error", err)
+		return JournalEntry{}, err
+	}
+
+	l.record(entry)
+	observability.Default().ObserveTransaction("ok", time.Since(start))
+	observability.Default().SetAccountBalance(fromAcct, fromBalance)
+	observability.Default().SetAccountBalance(toAcct, toBalance)
+	observability.Logger(ctx).Info("transfer settled", "from_account", fromAcct, "to_account", toAcct, "amount", amount, "journal_id", entry.ID)
+	return entry, nil
+}
+
+// EntriesSince returns the journal entries touching accountNumber,
+// either as debit or credit, with a Timestamp after since, in submission
+// order.
+func (l *Ledger) EntriesSince(accountNumber string, since time.Time) []JournalEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []JournalEntry
+	for _, entry := range l.entries {
+		if entry.DebitAcct != accountNumber && entry.CreditAcct != accountNumber {
+			continue
+		}
+		if entry.Timestamp.After(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// reserve claims idempotencyKey for the calling Transfer. If a transfer
+// with that key has already completed, it returns that JournalEntry
+// with done set. If another call currently holds the key, it returns a
+// channel the caller should wait on before reserving again. Otherwise it
+// reserves the key for the caller, who must call release when done.
+func (l *Ledger) reserve(idempotencyKey string) (entry JournalEntry, done bool, wait <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.byKey[idempotencyKey]; ok {
+		return entry, true, nil
+	}
+	if inFlight, ok := l.pending[idempotencyKey]; ok {
+		return JournalEntry{}, false, inFlight
+	}
+
+	l.pending[idempotencyKey] = make(chan struct{})
+	return JournalEntry{}, false, nil
+}
+
+// release clears idempotencyKey's reservation and wakes any callers
+// waiting on it.
+func (l *Ledger) release(idempotencyKey string) {
+	l.mu.Lock()
+	wait, ok := l.pending[idempotencyKey]
+	delete(l.pending, idempotencyKey)
+	l.mu.Unlock()
+
+	if ok {
+		close(wait)
+	}
+}
+
+func (l *Ledger) record(entry JournalEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry)
+	l.byKey[entry.IdempotencyKey] = entry
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func newTestLedger() *Ledger {
+	repo := NewMemoryRepository(map[string]Account{
+		"123456": {AccountNumber: "123456", CustomerName: "John Doe", Balance: 1000.0},
+		"654321": {AccountNumber: "654321", CustomerName: "Jane Doe", Balance: 2000.0},
+	})
+	return NewLedger(repo)
+}
+
+func TestLedgerTransfer(t *testing.T) {
+	l := newTestLedger()
+
+	entry, err := l.Transfer(context.Background(), "123456", "654321", 100, "key-1")
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if entry.DebitAcct != "123456" || entry.CreditAcct != "654321" || entry.Amount != 100 {
+		t.Fatalf("unexpected journal entry: %+v", entry)
+	}
+
+	from, err := l.repo.GetAccount("123456")
+	if err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	if from.Balance != 900 {
+		t.Fatalf("expected debited balance 900, got %.2f", from.Balance)
+	}
+
+	to, err := l.repo.GetAccount("654321")
+	if err != nil {
+		t.Fatalf("GetAccount failed: %v", err)
+	}
+	if to.Balance != 2100 {
+		t.Fatalf("expected credited balance 2100, got %.2f", to.Balance)
+	}
+}
+
+func TestLedgerTransferIdempotent(t *testing.T) {
+	l := newTestLedger()
+
+	first, err := l.Transfer(context.Background(), "123456", "654321", 100, "key-1")
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	second, err := l.Transfer(context.Background(), "123456", "654321", 100, "key-1")
+	if err != nil {
+		t.Fatalf("duplicate Transfer failed: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Fatalf("expected duplicate submission to return the original entry")
+	}
+
+	from, _ := l.repo.GetAccount("123456")
+	if from.Balance != 900 {
+		t.Fatalf("expected duplicate submission not to debit again, got %.2f", from.Balance)
+	}
+
+	if _, err := l.Transfer(context.Background(), "123456", "654321", 200, "key-1"); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Fatalf("expected ErrIdempotencyConflict, got %v", err)
+	}
+}
+
+func TestLedgerTransferConcurrentSameKeyAppliesOnce(t *testing.T) {
+	l := newTestLedger()
+
+	const callers = 20
+	var wg sync.WaitGroup
+	entries := make([]JournalEntry, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry, err := l.Transfer(context.Background(), "123456", "654321", 100, "same-key")
+			if err != nil {
+				t.Errorf("Transfer failed: %v", err)
+				return
+			}
+			entries[i] = entry
+		}(i)
+	}
+	wg.Wait()
+
+	from, _ := l.repo.GetAccount("123456")
+	if from.Balance != 900 {
+		t.Fatalf("expected the transfer to apply exactly once, balance got debited to %.2f", from.Balance)
+	}
+
+	for _, entry := range entries {
+		if entry.ID != entries[0].ID {
+			t.Fatalf("expected all concurrent callers to observe the same journal entry, got %+v and %+v", entries[0], entry)
+		}
+	}
+}
+
+func TestLedgerTransferInsufficientFunds(t *testing.T) {
+	l := newTestLedger()
+
+	if _, err := l.Transfer(context.Background(), "123456", "654321", 10000, "key-2"); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import "sync"
+
+// MemoryRepository is an in-memory Repository, preserving the behavior
+// of the original package-level accounts map.
+type MemoryRepository struct {
+	mu       sync.Mutex
+	accounts map[string]Account
+}
+
+// NewMemoryRepository creates a MemoryRepository seeded with the given
+// accounts.
+func NewMemoryRepository(seed map[string]Account) *MemoryRepository {
+	accounts := make(map[string]Account, len(seed))
+	for k, v := range seed {
+		accounts[k] = v
+	}
+	return &MemoryRepository{accounts: accounts}
+}
+
+func (r *MemoryRepository) GetAccount(accountNumber string) (Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, exists := r.accounts[accountNumber]
+	if !exists {
+		return Account{}, ErrAccountNotFound
+	}
+	return account, nil
+}
+
+func (r *MemoryRepository) CreateAccount(account Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts[account.AccountNumber] = account
+	return nil
+}
+
+func (r *MemoryRepository) ListByCustomer(customerName string) ([]Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var accounts []Account
+	for _, account := range r.accounts {
+		if account.CustomerName == customerName {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (r *MemoryRepository) UpdateBalance(accountNumber string, balance float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, exists := r.accounts[accountNumber]
+	if !exists {
+		return ErrAccountNotFound
+	}
+	account.Balance = balance
+	r.accounts[accountNumber] = account
+	return nil
+}
+
+// WithTx runs fn under the repository's single mutex, so the whole
+// closure observes a consistent view of the in-memory accounts. If fn
+// returns an error, the accounts are restored to their state before fn
+// ran.
+func (r *MemoryRepository) WithTx(fn func(Tx) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[string]Account, len(r.accounts))
+	for k, v := range r.accounts {
+		snapshot[k] = v
+	}
+	if err := fn(&memoryTx{repo: r}); err != nil {
+		r.accounts = snapshot
+		return err
+	}
+	return nil
+}
+
+// memoryTx is the Tx handed to WithTx closures; since MemoryRepository
+// already holds its mutex for the duration of WithTx, it can operate
+// directly on the backing map without re-locking.
+type memoryTx struct {
+	repo *MemoryRepository
+}
+
+func (tx *memoryTx) GetAccount(accountNumber string) (Account, error) {
+	account, exists := tx.repo.accounts[accountNumber]
+	if !exists {
+		return Account{}, ErrAccountNotFound
+	}
+	return account, nil
+}
+
+func (tx *memoryTx) UpdateBalance(accountNumber string, balance float64) error {
+	account, exists := tx.repo.accounts[accountNumber]
+	if !exists {
+		return ErrAccountNotFound
+	}
+	account.Balance = balance
+	tx.repo.accounts[accountNumber] = account
+	return nil
+}
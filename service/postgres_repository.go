@@ -0,0 +1,117 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// PostgresRepository is a Repository backed by a Postgres `accounts`
+// table (account_number text primary key, customer_name text, balance
+// double precision).
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a PostgresRepository over an already
+// configured *sql.DB.
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) GetAccount(accountNumber string) (Account, error) {
+	var account Account
+	row := r.db.QueryRow(
+		`SELECT account_number, customer_name, balance FROM accounts WHERE account_number = $1`,
+		accountNumber,
+	)
+	if err := row.Scan(&account.AccountNumber, &account.CustomerName, &account.Balance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Account{}, ErrAccountNotFound
+		}
+		return Account{}, err
+	}
+	return account, nil
+}
+
+func (r *PostgresRepository) CreateAccount(account Account) error {
+	_, err := r.db.Exec(
+		`INSERT INTO accounts (account_number, customer_name, balance) VALUES ($1, $2, $3)`,
+		account.AccountNumber, account.CustomerName, account.Balance,
+	)
+	return err
+}
+
+func (r *PostgresRepository) ListByCustomer(customerName string) ([]Account, error) {
+	rows, err := r.db.Query(
+		`SELECT account_number, customer_name, balance FROM accounts WHERE customer_name = $1`,
+		customerName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var account Account
+		if err := rows.Scan(&account.AccountNumber, &account.CustomerName, &account.Balance); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *PostgresRepository) UpdateBalance(accountNumber string, balance float64) error {
+	_, err := r.db.Exec(
+		`UPDATE accounts SET balance = $1 WHERE account_number = $2`,
+		balance, accountNumber,
+	)
+	return err
+}
+
+// WithTx runs fn inside a Postgres transaction, committing on success
+// and rolling back otherwise.
+func (r *PostgresRepository) WithTx(fn func(Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&postgresTx{tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// postgresTx is the Tx handed to WithTx closures. GetAccount takes a
+// row-level lock with FOR UPDATE so concurrent transfers touching the
+// same account serialize on Postgres rather than racing in the
+// application.
+type postgresTx struct {
+	tx *sql.Tx
+}
+
+func (tx *postgresTx) GetAccount(accountNumber string) (Account, error) {
+	var account Account
+	row := tx.tx.QueryRow(
+		`SELECT account_number, customer_name, balance FROM accounts WHERE account_number = $1 FOR UPDATE`,
+		accountNumber,
+	)
+	if err := row.Scan(&account.AccountNumber, &account.CustomerName, &account.Balance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Account{}, ErrAccountNotFound
+		}
+		return Account{}, err
+	}
+	return account, nil
+}
+
+func (tx *postgresTx) UpdateBalance(accountNumber string, balance float64) error {
+	_, err := tx.tx.Exec(
+		`UPDATE accounts SET balance = $1 WHERE account_number = $2`,
+		balance, accountNumber,
+	)
+	return err
+}
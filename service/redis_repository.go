@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var redisCtx = context.Background()
+
+// RedisRepository is a Repository backed by Redis, storing each account
+// as a hash under key "account:<accountNumber>".
+type RedisRepository struct {
+	client *redis.Client
+	mu     sync.Mutex
+}
+
+// NewRedisRepository creates a RedisRepository over an already
+// configured *redis.Client.
+func NewRedisRepository(client *redis.Client) *RedisRepository {
+	return &RedisRepository{client: client}
+}
+
+func accountKey(accountNumber string) string {
+	return fmt.Sprintf("account:%s", accountNumber)
+}
+
+func (r *RedisRepository) GetAccount(accountNumber string) (Account, error) {
+	values, err := r.client.HGetAll(redisCtx, accountKey(accountNumber)).Result()
+	if err != nil {
+		return Account{}, err
+	}
+	if len(values) == 0 {
+		return Account{}, ErrAccountNotFound
+	}
+	return accountFromHash(accountNumber, values)
+}
+
+func (r *RedisRepository) CreateAccount(account Account) error {
+	return r.client.HSet(redisCtx, accountKey(account.AccountNumber),
+		"customerName", account.CustomerName,
+		"balance", strconv.FormatFloat(account.Balance, 'f', -1, 64),
+	).Err()
+}
+
+func (r *RedisRepository) ListByCustomer(customerName string) ([]Account, error) {
+	keys, err := r.client.Keys(redisCtx, "account:*").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []Account
+	for _, key := range keys {
+		values, err := r.client.HGetAll(redisCtx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		if values["customerName"] != customerName {
+			continue
+		}
+
+		account, err := accountFromHash(strings.TrimPrefix(key, "account:"), values)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+func (r *RedisRepository) UpdateBalance(accountNumber string, balance float64) error {
+	return r.client.HSet(redisCtx, accountKey(accountNumber), "balance", strconv.FormatFloat(balance, 'f', -1, 64)).Err()
+}
+
+func accountFromHash(accountNumber string, values map[string]string) (Account, error) {
+	balance, err := strconv.ParseFloat(values["balance"], 64)
+	if err != nil {
+		return Account{}, fmt.Errorf("parsing balance for account %s: %w", accountNumber, err)
+	}
+	return Account{AccountNumber: accountNumber, CustomerName: values["customerName"], Balance: balance}, nil
+}
+
+// WithTx serializes fn behind the repository's mutex so the read-modify-
+// write sequences AccountService performs (get balance, then update it)
+// don't race with another WithTx call on this RedisRepository. It opens
+// no Redis transaction: each write through the Tx is applied to Redis
+// as it is made, and writes already made are not undone if fn returns
+// an error.
+func (r *RedisRepository) WithTx(fn func(Tx) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return fn(&redisTx{repo: r})
+}
+
+// redisTx is the Tx handed to WithTx closures; it simply delegates to
+// the repository, which is already holding its mutex for the duration.
+type redisTx struct {
+	repo *RedisRepository
+}
+
+func (tx *redisTx) GetAccount(accountNumber string) (Account, error) {
+	return tx.repo.GetAccount(accountNumber)
+}
+
+func (tx *redisTx) UpdateBalance(accountNumber string, balance float64) error {
+	return tx.repo.UpdateBalance(accountNumber, balance)
+}
@@ -0,0 +1,29 @@
+package service
+
+import "fmt"
+
+// ErrAccountNotFound is returned by a Repository when no account matches
+// the requested account number.
+var ErrAccountNotFound = fmt.Errorf("account not found")
+
+// Tx is a unit of work handed to the function passed to
+// Repository.WithTx. All reads and writes made through it participate in
+// the same underlying transaction.
+type Tx interface {
+	GetAccount(accountNumber string) (Account, error)
+	UpdateBalance(accountNumber string, balance float64) error
+}
+
+// Repository abstracts account persistence so AccountService can run
+// against in-memory, Postgres, or Redis-backed storage without changing
+// its business logic.
+type Repository interface {
+	GetAccount(accountNumber string) (Account, error)
+	CreateAccount(account Account) error
+	ListByCustomer(customerName string) ([]Account, error)
+	UpdateBalance(accountNumber string, balance float64) error
+
+	// WithTx runs fn against a transaction-scoped Tx, committing if fn
+	// returns nil and rolling back otherwise.
+	WithTx(fn func(Tx) error) error
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"sync"
 	"time"
+
+	"horten/observability"
 )
 
 // Account represents account details.
@@ -13,31 +15,89 @@ type Account struct {
 	Balance       float64 `json:"balance"`
 }
 
-// Mock data for accounts
-var accounts = map[string]Account{
+// DefaultAccounts seeds a MemoryRepository with the demo data the
+// service previously hardcoded.
+var DefaultAccounts = map[string]Account{
 	"123456": {AccountNumber: "123456", CustomerName: "John Doe", Balance: 1000.0},
 	"654321": {AccountNumber: "654321", CustomerName: "Jane Doe", Balance: 2000.0},
 }
 
+// ErrInsufficientFunds is returned by Debit when amount would overdraw
+// the account.
+var ErrInsufficientFunds = fmt.Errorf("insufficient funds")
+
 // AccountService handles business logic related to accounts.
 type AccountService struct {
-	mutex sync.Mutex
+	repo Repository
 }
 
-// NewAccountService creates a new AccountService.
-func NewAccountService() *AccountService {
-	return &AccountService{}
+// NewAccountService creates a new AccountService backed by repo.
+func NewAccountService(repo Repository) *AccountService {
+	return &AccountService{repo: repo}
 }
 
-// GetBalance fetches the balance for a given account number.
-func (s *AccountService) GetBalance(accountNumber string) (Account, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	if account, exists := accounts[accountNumber]; exists {
+// GetBalance fetches the balance for a given account number. ctx's
+// correlation ID, if any, is attached to the log lines it emits.
+func (s *AccountService) GetBalance(ctx context.Context, accountNumber string) (Account, error) {
+	var account Account
+	err := s.repo.WithTx(func(tx Tx) error {
 		// Simulate concurrency with artificial delay
 		time.Sleep(100 * time.Millisecond)
-		return account, nil
+
+		var err error
+		account, err = tx.GetAccount(accountNumber)
+		return err
+	})
+	if err != nil {
+		observability.Logger(ctx).Error("get balance failed", "account_number", accountNumber, "error", err)
+		return Account{}, err
+	}
+	observability.Default().SetAccountBalance(accountNumber, account.Balance)
+	return account, nil
+}
+
+// Debit subtracts amount from the account's balance, rejecting the
+// operation if it would overdraw the account. ctx's correlation ID, if
+// any, is attached to the log lines it emits.
+func (s *AccountService) Debit(ctx context.Context, accountNumber string, amount float64) error {
+	var balance float64
+	err := s.repo.WithTx(func(tx Tx) error {
+		account, err := tx.GetAccount(accountNumber)
+		if err != nil {
+			return err
+		}
+		if account.Balance-amount < 0 {
+			return fmt.Errorf("account %s: %w", accountNumber, ErrInsufficientFunds)
+		}
+		balance = account.Balance - amount
+		return tx.UpdateBalance(accountNumber, balance)
+	})
+	if err != nil {
+		observability.Logger(ctx).Error("debit failed", "account_number", accountNumber, "amount", amount, "error", err)
+		return err
+	}
+	observability.Logger(ctx).Info("debit applied", "account_number", accountNumber, "amount", amount)
+	observability.Default().SetAccountBalance(accountNumber, balance)
+	return nil
+}
+
+// Credit adds amount to the account's balance. ctx's correlation ID, if
+// any, is attached to the log lines it emits.
+func (s *AccountService) Credit(ctx context.Context, accountNumber string, amount float64) error {
+	var balance float64
+	err := s.repo.WithTx(func(tx Tx) error {
+		account, err := tx.GetAccount(accountNumber)
+		if err != nil {
+			return err
+		}
+		balance = account.Balance + amount
+		return tx.UpdateBalance(accountNumber, balance)
+	})
+	if err != nil {
+		observability.Logger(ctx).Error("credit failed", "account_number", accountNumber, "amount", amount, "error", err)
+		return err
 	}
-	return Account{}, fmt.Errorf("account not found")
+	observability.Logger(ctx).Info("credit applied", "account_number", accountNumber, "amount", amount)
+	observability.Default().SetAccountBalance(accountNumber, balance)
+	return nil
 }
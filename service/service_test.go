@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func newTestService() *AccountService {
+	repo := NewMemoryRepository(map[string]Account{
+		"123456": {AccountNumber: "123456", CustomerName: "John Doe", Balance: 1000.0},
+	})
+	return NewAccountService(repo)
+}
+
+func TestAccountServiceGetBalance(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	account, err := s.GetBalance(ctx, "123456")
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if account.Balance != 1000.0 {
+		t.Fatalf("expected balance 1000.0, got %.2f", account.Balance)
+	}
+
+	if _, err := s.GetBalance(ctx, "000000"); err == nil {
+		t.Fatalf("expected error for unknown account")
+	}
+}
+
+func TestAccountServiceDebitCredit(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	if err := s.Debit(ctx, "123456", 200); err != nil {
+		t.Fatalf("Debit failed: %v", err)
+	}
+	if err := s.Credit(ctx, "123456", 50); err != nil {
+		t.Fatalf("Credit failed: %v", err)
+	}
+
+	account, err := s.GetBalance(ctx, "123456")
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if account.Balance != 850.0 {
+		t.Fatalf("expected balance 850.0, got %.2f", account.Balance)
+	}
+
+	if err := s.Debit(ctx, "123456", 10000); err == nil {
+		t.Fatalf("expected insufficient funds error")
+	}
+}
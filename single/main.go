@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
+
+	"horten/observability"
 )
 
 // Account represents a bank account
@@ -22,18 +26,23 @@ type Transaction struct {
 func (a *Account) ProcessTransaction(amount float64, wg *sync.WaitGroup) {
 	defer wg.Done() // Notify when the goroutine is finished
 
+	start := time.Now()
+	ctx := context.Background()
+
 	// Lock the account to prevent race conditions
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	if amount < 0 && a.Balance+amount < 0 {
-		fmt.Printf("Insufficient funds for account %s\n", a.AccountNumber)
+		observability.Default().ObserveTransaction("insufficient", time.Since(start))
+		observability.Logger(ctx).Warn("insufficient funds", "account_number", a.AccountNumber, "amount", amount)
 		return
 	}
 
 	a.Balance += amount
-	fmt.Printf("Processed transaction of %.2f on account %s. New balance: %.2f\n",
-		amount, a.AccountNumber, a.Balance)
+	observability.Default().ObserveTransaction("ok", time.Since(start))
+	observability.Default().SetAccountBalance(a.AccountNumber, a.Balance)
+	observability.Logger(ctx).Info("transaction processed", "account_number", a.AccountNumber, "amount", amount, "balance", a.Balance)
 }
 
 func main() {
@@ -0,0 +1,76 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisLastSeenStore persists each asset's lastSeenTime under key
+// "deposit:<asset>:lastSeen" so a restarted watcher resumes from where
+// it left off instead of re-processing old deposits.
+type RedisLastSeenStore struct {
+	client *redis.Client
+}
+
+// NewRedisLastSeenStore creates a RedisLastSeenStore over an already
+// configured *redis.Client.
+func NewRedisLastSeenStore(client *redis.Client) *RedisLastSeenStore {
+	return &RedisLastSeenStore{client: client}
+}
+
+func (s *RedisLastSeenStore) LastSeen(asset string) (time.Time, error) {
+	val, err := s.client.Get(context.Background(), lastSeenKey(asset)).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, val)
+}
+
+func (s *RedisLastSeenStore) SetLastSeen(asset string, t time.Time) error {
+	return s.client.Set(context.Background(), lastSeenKey(asset), t.Format(time.RFC3339Nano), 0).Err()
+}
+
+func lastSeenKey(asset string) string {
+	return fmt.Sprintf("deposit:%s:lastSeen", asset)
+}
+
+// RedisDepositSource reads deposits recorded by an external ingestion
+// process into a Redis sorted set ("deposits"), scored by the deposit's
+// Unix-nanosecond timestamp and stored as JSON.
+type RedisDepositSource struct {
+	client *redis.Client
+}
+
+// NewRedisDepositSource creates a RedisDepositSource over an already
+// configured *redis.Client.
+func NewRedisDepositSource(client *redis.Client) *RedisDepositSource {
+	return &RedisDepositSource{client: client}
+}
+
+func (s *RedisDepositSource) ListSince(t time.Time) ([]Deposit, error) {
+	members, err := s.client.ZRangeByScore(context.Background(), "deposits", &redis.ZRangeBy{
+		Min: strconv.FormatInt(t.UnixNano(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deposits := make([]Deposit, 0, len(members))
+	for _, member := range members {
+		var deposit Deposit
+		if err := json.Unmarshal([]byte(member), &deposit); err != nil {
+			return nil, fmt.Errorf("decoding deposit: %w", err)
+		}
+		deposits = append(deposits, deposit)
+	}
+	return deposits, nil
+}
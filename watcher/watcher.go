@@ -0,0 +1,229 @@
+package watcher
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"horten/service"
+)
+
+// depositRateLimit caps how many settlement transfers per second the
+// watcher will issue for a single asset, so a burst of deposits can't
+// overwhelm the ledger.
+const depositRateLimit = 5
+
+// Deposit represents an incoming credit observed on a DepositSource.
+type Deposit struct {
+	ID        string
+	Asset     string
+	Account   string
+	Amount    float64
+	Confirmed bool
+	Timestamp time.Time
+}
+
+// DepositSource is polled by DepositWatcher for new deposits.
+type DepositSource interface {
+	ListSince(t time.Time) ([]Deposit, error)
+}
+
+// LastSeenStore persists, per asset, the timestamp of the most recently
+// processed deposit so a restart doesn't re-process old deposits.
+type LastSeenStore interface {
+	LastSeen(asset string) (time.Time, error)
+	SetLastSeen(asset string, t time.Time) error
+}
+
+// Ledger is the transfer capability the watcher settles deposits
+// through. *service.Ledger satisfies this directly, so deposit-settled
+// transfers go through the same atomic, journaled transfer path as the
+// HTTP API and show up in GET /account/{n}/transactions.
+type Ledger interface {
+	Transfer(ctx context.Context, fromAcct, toAcct string, amount float64, idempotencyKey string) (service.JournalEntry, error)
+}
+
+// DepositWatcher polls a DepositSource per configured asset and, for
+// each new confirmed deposit, settles it with a Ledger transfer from the
+// asset's settlement account to the deposit's target account.
+type DepositWatcher struct {
+	source             DepositSource
+	ledger             Ledger
+	store              LastSeenStore
+	settlementAccounts map[string]string // asset -> settlement account number
+	pollInterval       time.Duration
+
+	limitersMu sync.Mutex
+	limiters   map[string]*rate.Limiter
+
+	mu               sync.Mutex
+	watchingDeposits map[string]struct{}
+
+	wg sync.WaitGroup
+}
+
+// NewDepositWatcher creates a DepositWatcher that settles deposits for
+// the given assets, debiting the settlementAccounts entry for each
+// deposit's asset.
+func NewDepositWatcher(source DepositSource, ledger Ledger, store LastSeenStore, settlementAccounts map[string]string, pollInterval time.Duration) *DepositWatcher {
+	return &DepositWatcher{
+		source:             source,
+		ledger:             ledger,
+		store:              store,
+		settlementAccounts: settlementAccounts,
+		pollInterval:       pollInterval,
+		limiters:           make(map[string]*rate.Limiter),
+		watchingDeposits:   make(map[string]struct{}),
+	}
+}
+
+// Run polls the DepositSource every pollInterval until ctx is cancelled.
+// Call Wait afterwards to drain any settlement transfers still in
+// flight.
+func (w *DepositWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.pollOnce(ctx)
+		}
+	}
+}
+
+// Wait blocks until all settlement transfers started by Run have
+// completed. Intended to be called after cancelling Run's context during
+// graceful shutdown.
+func (w *DepositWatcher) Wait() {
+	w.wg.Wait()
+}
+
+func (w *DepositWatcher) pollOnce(ctx context.Context) {
+	for asset, settlementAcct := range w.settlementAccounts {
+		since, err := w.store.LastSeen(asset)
+		if err != nil {
+			log.Printf("watcher: could not load last seen time for %s: %v", asset, err)
+			continue
+		}
+
+		deposits, err := w.source.ListSince(since)
+		if err != nil {
+			log.Printf("watcher: could not list deposits for %s: %v", asset, err)
+			continue
+		}
+
+		var matched []Deposit
+		for _, deposit := range deposits {
+			if deposit.Asset != asset || !deposit.Confirmed {
+				continue
+			}
+			if !w.claim(deposit.ID) {
+				continue
+			}
+			matched = append(matched, deposit)
+		}
+		sort.Slice(matched, func(i, j int) bool {
+			return matched[i].Timestamp.Before(matched[j].Timestamp)
+		})
+
+		// Settle this poll's batch concurrently, but wait for every
+		// settlement to finish before touching the watermark: advancing
+		// lastSeenTime for a deposit whose settle() hasn't actually
+		// completed (or failed) would let it silently drop off ListSince
+		// on the next poll with nothing having settled it.
+		settled := make([]bool, len(matched))
+		var batch sync.WaitGroup
+		for i, deposit := range matched {
+			batch.Add(1)
+			w.wg.Add(1)
+			go func(i int, deposit Deposit) {
+				defer w.wg.Done()
+				defer batch.Done()
+				settled[i] = w.settle(ctx, settlementAcct, deposit)
+			}(i, deposit)
+		}
+		batch.Wait()
+
+		// Only advance past the longest prefix (by timestamp) of deposits
+		// that settled successfully, so the watermark never skips past an
+		// unresolved one. Every failed deposit in the batch is unclaimed,
+		// not just the first, so a later failure in the same poll is also
+		// retried next time instead of being stuck in watchingDeposits
+		// forever.
+		latest := since
+		stopped := false
+		for i, deposit := range matched {
+			if !settled[i] {
+				w.unclaim(deposit.ID)
+				stopped = true
+				continue
+			}
+			if !stopped && deposit.Timestamp.After(latest) {
+				latest = deposit.Timestamp
+			}
+		}
+
+		if latest.After(since) {
+			if err := w.store.SetLastSeen(asset, latest); err != nil {
+				log.Printf("watcher: could not persist last seen time for %s: %v", asset, err)
+			}
+		}
+	}
+}
+
+// claim reports whether depositID has not been seen before, recording it
+// if so. It dedupes a deposit that shows up again before its Timestamp
+// has rolled lastSeenTime forward.
+func (w *DepositWatcher) claim(depositID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, seen := w.watchingDeposits[depositID]; seen {
+		return false
+	}
+	w.watchingDeposits[depositID] = struct{}{}
+	return true
+}
+
+// unclaim releases a claimed depositID so a failed settlement is
+// retried on a subsequent poll instead of being dropped for good.
+func (w *DepositWatcher) unclaim(depositID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.watchingDeposits, depositID)
+}
+
+func (w *DepositWatcher) limiterFor(asset string) *rate.Limiter {
+	w.limitersMu.Lock()
+	defer w.limitersMu.Unlock()
+
+	limiter, ok := w.limiters[asset]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(depositRateLimit), depositRateLimit)
+		w.limiters[asset] = limiter
+	}
+	return limiter
+}
+
+// settle issues the ledger transfer for deposit and reports whether it
+// succeeded, so pollOnce can decide whether it's safe to advance past it.
+func (w *DepositWatcher) settle(ctx context.Context, settlementAcct string, deposit Deposit) bool {
+	if err := w.limiterFor(deposit.Asset).Wait(ctx); err != nil {
+		log.Printf("watcher: rate limit wait for deposit %s: %v", deposit.ID, err)
+		return false
+	}
+
+	if _, err := w.ledger.Transfer(ctx, settlementAcct, deposit.Account, deposit.Amount, deposit.ID); err != nil {
+		log.Printf("watcher: settling deposit %s failed: %v", deposit.ID, err)
+		return false
+	}
+	return true
+}
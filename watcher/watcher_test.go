@@ -0,0 +1,119 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"horten/service"
+)
+
+type fakeSource struct {
+	deposits []Deposit
+}
+
+// ListSince ignores t and always returns every deposit, so tests can
+// exercise the watcher's own watchingDeposits dedupe rather than relying
+// on the since filter to skip already-processed deposits.
+func (s *fakeSource) ListSince(t time.Time) ([]Deposit, error) {
+	return s.deposits, nil
+}
+
+type fakeStore struct {
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{lastSeen: make(map[string]time.Time)}
+}
+
+func (s *fakeStore) LastSeen(asset string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSeen[asset], nil
+}
+
+func (s *fakeStore) SetLastSeen(asset string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSeen[asset] = t
+	return nil
+}
+
+type fakeLedger struct {
+	mu        sync.Mutex
+	transfers []string
+	fail      map[string]bool
+}
+
+func (l *fakeLedger) Transfer(ctx context.Context, fromAcct, toAcct string, amount float64, idempotencyKey string) (service.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.fail[idempotencyKey] {
+		return service.JournalEntry{}, errors.New("settlement failed")
+	}
+	l.transfers = append(l.transfers, idempotencyKey)
+	return service.JournalEntry{ID: idempotencyKey}, nil
+}
+
+func (l *fakeLedger) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.transfers)
+}
+
+func TestDepositWatcherSettlesNewDepositsOnce(t *testing.T) {
+	// The source doesn't respect "since" perfectly here (it keeps
+	// returning dep-1 on every poll), exercising the watcher's own
+	// watchingDeposits dedupe rather than relying on LastSeenStore alone.
+	source := &fakeSource{deposits: []Deposit{
+		{ID: "dep-1", Asset: "USD", Account: "123456", Amount: 100, Confirmed: true, Timestamp: time.Unix(100, 0)},
+		{ID: "dep-2", Asset: "USD", Account: "123456", Amount: 50, Confirmed: false, Timestamp: time.Unix(101, 0)},
+	}}
+	store := newFakeStore()
+	ledger := &fakeLedger{}
+
+	w := NewDepositWatcher(source, ledger, store, map[string]string{"USD": "654321"}, time.Millisecond)
+
+	w.pollOnce(context.Background())
+	w.pollOnce(context.Background())
+	w.Wait()
+
+	if got := ledger.count(); got != 1 {
+		t.Fatalf("expected 1 settled transfer (unconfirmed deposit skipped, confirmed one deduped), got %d", got)
+	}
+}
+
+func TestDepositWatcherRetriesEveryFailedDepositInABatch(t *testing.T) {
+	// d1 settles, d2 and d3 both fail in the same poll. Both, not just
+	// the first, must be retried once the ledger recovers.
+	source := &fakeSource{deposits: []Deposit{
+		{ID: "dep-1", Asset: "USD", Account: "123456", Amount: 10, Confirmed: true, Timestamp: time.Unix(100, 0)},
+		{ID: "dep-2", Asset: "USD", Account: "123456", Amount: 20, Confirmed: true, Timestamp: time.Unix(101, 0)},
+		{ID: "dep-3", Asset: "USD", Account: "123456", Amount: 30, Confirmed: true, Timestamp: time.Unix(102, 0)},
+	}}
+	store := newFakeStore()
+	ledger := &fakeLedger{fail: map[string]bool{"dep-2": true, "dep-3": true}}
+
+	w := NewDepositWatcher(source, ledger, store, map[string]string{"USD": "654321"}, time.Millisecond)
+
+	w.pollOnce(context.Background())
+	w.Wait()
+
+	if got := ledger.count(); got != 1 {
+		t.Fatalf("expected only dep-1 to settle on the first poll, got %d settled", got)
+	}
+
+	// The ledger recovers; a follow-up poll must retry both dep-2 and
+	// dep-3, not just the one that failed first.
+	ledger.fail = nil
+	w.pollOnce(context.Background())
+	w.Wait()
+
+	if got := ledger.count(); got != 3 {
+		t.Fatalf("expected dep-2 and dep-3 to be retried and settle, got %d settled total", got)
+	}
+}